@@ -0,0 +1,37 @@
+package timewheel_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HuXin0817/timewheel"
+)
+
+// TestAfterFunc checks that the callback runs once the timer is due, and
+// that Stop on an already-fired func-mode timer is a harmless no-op
+// rather than blocking on a nil channel.
+func TestAfterFunc(t *testing.T) {
+	clock := timewheel.NewManualClock(time.Unix(0, 0))
+	tw := timewheel.NewWithClock(100*time.Millisecond, clock)
+	defer tw.Stop()
+
+	var ran atomic.Bool
+	timer := tw.AfterFunc(200*time.Millisecond, func() { ran.Store(true) })
+
+	clock.Advance(200 * time.Millisecond)
+	if !ran.Load() {
+		t.Fatal("AfterFunc callback did not run by the time Advance returned")
+	}
+
+	timer.Stop() // must not block or panic on an already-fired func-mode timer
+}
+
+// TestSleep exercises the package-level default wheel.
+func TestSleep(t *testing.T) {
+	start := timewheel.Now()
+	timewheel.Sleep(20 * time.Millisecond)
+	if !timewheel.Now().After(start) {
+		t.Fatal("Sleep returned without the default wheel's clock advancing")
+	}
+}
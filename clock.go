@@ -0,0 +1,154 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock abstracts the passage of time that drives a TimeWheel. New uses
+// the real wall clock; tests can substitute a ManualClock via
+// NewWithClock to advance the wheel deterministically without sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) ClockTicker
+}
+
+// ClockTicker is the minimal ticker surface a Clock must provide: a
+// channel of ticks plus the same Stop/Reset controls as *time.Ticker.
+type ClockTicker interface {
+	Chan() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// tickObserver is an optional extension a ClockTicker may implement so
+// that, once newTimeWheel's loop has fully processed a tick, it can
+// notify whoever is driving the clock. ManualClock's ticker uses this to
+// make Advance block until the TimeWheel has caught up; realTicker does
+// not implement it, since nothing needs to wait on the wall clock.
+type tickObserver interface {
+	observeTick()
+}
+
+// realClock drives a TimeWheel off the actual wall clock. It is the
+// Clock that New wires up by default.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) ClockTicker {
+	return &realTicker{ticker: time.NewTicker(d)}
+}
+
+// realTicker adapts *time.Ticker to the ClockTicker interface.
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t *realTicker) Chan() <-chan time.Time { return t.ticker.C }
+
+func (t *realTicker) Stop() { t.ticker.Stop() }
+
+func (t *realTicker) Reset(d time.Duration) { t.ticker.Reset(d) }
+
+// ManualClock is a Clock whose notion of time only moves when Advance is
+// called, letting a TimeWheel built via NewWithClock be driven
+// deterministically in tests instead of waiting on the wall clock.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*manualTicker
+}
+
+// NewManualClock creates a ManualClock starting at the given time.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current, manually-advanced time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker registers a new manual ticker on this clock.
+func (c *ManualClock) NewTicker(d time.Duration) ClockTicker {
+	t := &manualTicker{interval: d, ch: make(chan time.Time), ack: make(chan struct{})}
+	c.mu.Lock()
+	c.tickers = append(c.tickers, t)
+	c.mu.Unlock()
+	return t
+}
+
+// Advance moves the clock forward by d, synchronously pushing one tick
+// per whole interval elapsed onto every ticker created from this clock
+// and blocking until the owning TimeWheel has fully processed each one
+// before pushing the next. So tw.NewTimer(500*time.Millisecond) followed
+// by clock.Advance(500*time.Millisecond) is guaranteed to have fired the
+// timer by the time Advance returns. A remainder of d shorter than a
+// ticker's interval produces no tick and is not carried over to the next
+// Advance.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	start := c.now
+	tickers := append([]*manualTicker(nil), c.tickers...)
+	c.mu.Unlock()
+
+	for _, t := range tickers {
+		if t.stopped.Load() {
+			continue
+		}
+		interval := t.currentInterval()
+		if interval <= 0 {
+			continue
+		}
+		now := start
+		for n := d / interval; n > 0; n-- {
+			now = now.Add(interval)
+			c.mu.Lock()
+			if now.After(c.now) {
+				c.now = now
+			}
+			c.mu.Unlock()
+			t.ch <- now
+			<-t.ack
+		}
+	}
+
+	c.mu.Lock()
+	c.now = start.Add(d)
+	c.mu.Unlock()
+}
+
+// manualTicker is the ClockTicker handed out by ManualClock. Besides
+// Chan/Stop/Reset it implements tickObserver so Advance can wait for a
+// pushed tick to be fully processed.
+type manualTicker struct {
+	mu       sync.Mutex
+	interval time.Duration
+	ch       chan time.Time
+	ack      chan struct{}
+	stopped  atomic.Bool
+}
+
+func (t *manualTicker) Chan() <-chan time.Time { return t.ch }
+
+func (t *manualTicker) Stop() { t.stopped.Store(true) }
+
+func (t *manualTicker) Reset(d time.Duration) {
+	t.mu.Lock()
+	t.interval = d
+	t.mu.Unlock()
+}
+
+func (t *manualTicker) currentInterval() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.interval
+}
+
+func (t *manualTicker) observeTick() {
+	t.ack <- struct{}{}
+}
@@ -0,0 +1,33 @@
+package timewheel_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HuXin0817/timewheel"
+)
+
+// TestManualClockAdvanceFiresTimer checks the "logical ticker" contract:
+// once Advance(d) returns, every timer due within d has already fired,
+// with no wall-clock sleep required.
+func TestManualClockAdvanceFiresTimer(t *testing.T) {
+	clock := timewheel.NewManualClock(time.Unix(0, 0))
+	tw := timewheel.NewWithClock(100*time.Millisecond, clock)
+	defer tw.Stop()
+
+	timer := tw.NewTimer(500 * time.Millisecond)
+
+	clock.Advance(400 * time.Millisecond)
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+
+	clock.Advance(100 * time.Millisecond)
+	select {
+	case <-timer.C:
+	default:
+		t.Fatal("timer did not fire by the time Advance returned")
+	}
+}
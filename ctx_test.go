@@ -0,0 +1,55 @@
+package timewheel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HuXin0817/timewheel"
+)
+
+// TestAfterCtxCancelStopsAndClosesC checks that cancelling ctx before the
+// deadline stops the timer and closes C, instead of leaving it abandoned
+// in the wheel.
+func TestAfterCtxCancelStopsAndClosesC(t *testing.T) {
+	clock := timewheel.NewManualClock(time.Unix(0, 0))
+	tw := timewheel.NewWithClock(100*time.Millisecond, clock)
+	defer tw.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	timer := tw.AfterCtx(ctx, 500*time.Millisecond)
+
+	cancel()
+	clock.Advance(100 * time.Millisecond) // let the reaper observe ctx.Done() on the next tick
+
+	select {
+	case _, ok := <-timer.C:
+		if ok {
+			t.Fatal("expected C to be closed, got a value instead")
+		}
+	default:
+		t.Fatal("C was not closed after ctx was cancelled")
+	}
+}
+
+// TestScheduleRunsWithDeadlineCtx checks that Schedule hands its callback
+// a context carrying the timer's own deadline.
+func TestScheduleRunsWithDeadlineCtx(t *testing.T) {
+	clock := timewheel.NewManualClock(time.Unix(0, 0))
+	tw := timewheel.NewWithClock(100*time.Millisecond, clock)
+	defer tw.Stop()
+
+	done := make(chan struct{})
+	var sawDeadline bool
+	tw.Schedule(200*time.Millisecond, func(ctx context.Context) {
+		_, sawDeadline = ctx.Deadline()
+		close(done)
+	})
+
+	clock.Advance(200 * time.Millisecond)
+	<-done
+
+	if !sawDeadline {
+		t.Fatal("Schedule did not pass a context carrying a deadline")
+	}
+}
@@ -0,0 +1,96 @@
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ctxEntry pairs a context with the action to run once it is done, plus a
+// way to notice that the watched timer/ticker has already settled on its
+// own (fired, or been stopped directly) so the entry can be dropped
+// without waiting on ctx.
+type ctxEntry struct {
+	ctx    context.Context
+	done   func() bool
+	cancel func()
+}
+
+// ctxReaper batches context-cancellation watching for every timer and
+// ticker registered via AfterCtx/NewTickerCtx: rather than spawning a
+// goroutine per registration to select on ctx.Done(), every owning
+// TimeWheel keeps one ctxReaper and checks it once per tick from its own
+// tick goroutine.
+type ctxReaper struct {
+	mu      sync.Mutex
+	entries []ctxEntry
+}
+
+// watch registers ctx, running cancel the next time check observes it
+// done. done is polled first on every check; once it reports true the
+// entry is dropped without waiting for ctx, so a timer/ticker that
+// settles on its own (fires, or is stopped directly) doesn't pin its
+// entry here for the lifetime of a long-lived ctx.
+func (r *ctxReaper) watch(ctx context.Context, done func() bool, cancel func()) {
+	r.mu.Lock()
+	r.entries = append(r.entries, ctxEntry{ctx: ctx, done: done, cancel: cancel})
+	r.mu.Unlock()
+}
+
+// check runs cancel for, and drops, every watched entry whose context has
+// finished, and silently drops any entry whose done already reports true.
+// Called once per tick by newTimeWheel's loop.
+func (r *ctxReaper) check() {
+	r.mu.Lock()
+	live := r.entries[:0]
+	for _, e := range r.entries {
+		if e.done() {
+			continue
+		}
+		select {
+		case <-e.ctx.Done():
+			e.cancel()
+		default:
+			live = append(live, e)
+		}
+	}
+	r.entries = live
+	r.mu.Unlock()
+}
+
+// AfterCtx returns a Timer like NewTimer, but also stops the timer and
+// closes C if ctx is cancelled before it fires. This avoids the classic
+// leak where a caller does select { case <-ctx.Done(): ...; case
+// <-timer.C: ... } and abandons the timer in the wheel once ctx wins.
+func (tw *TimeWheel) AfterCtx(ctx context.Context, d time.Duration) *Timer {
+	timer := tw.NewTimer(d)
+	tw.ctxReaper.watch(ctx, func() bool { return timer.state.Load() != timerActive }, func() {
+		if timer.Stop() {
+			close(timer.C)
+		}
+	})
+	return timer
+}
+
+// NewTickerCtx returns a Ticker like NewTicker, but also stops the ticker
+// and closes C once ctx is cancelled.
+func (tw *TimeWheel) NewTickerCtx(ctx context.Context, d time.Duration) *Ticker {
+	ticker := tw.NewTicker(d)
+	tw.ctxReaper.watch(ctx, ticker.stop.Load, func() {
+		if ticker.stopNoSend() {
+			close(ticker.C)
+		}
+	})
+	return ticker
+}
+
+// Schedule runs f once d has elapsed, like AfterFunc, but hands f a
+// context derived from the timer's own scheduled deadline so that f can
+// propagate the same cancellation into whatever it starts.
+func (tw *TimeWheel) Schedule(d time.Duration, f func(context.Context)) *Timer {
+	ctx, cancel := context.WithDeadline(context.Background(), tw.Now().Add(d))
+	return tw.AfterFunc(d, func() {
+		defer cancel()
+		f(ctx)
+	})
+}
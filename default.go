@@ -0,0 +1,52 @@
+package timewheel
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	defaultWheel     *TimeWheel
+	defaultWheelOnce sync.Once
+)
+
+// defaultTimeWheel lazily builds the package-level TimeWheel shared by
+// After, AfterFunc, Future, Sleep and Now.
+func defaultTimeWheel() *TimeWheel {
+	defaultWheelOnce.Do(func() {
+		defaultWheel = New(minInterval)
+	})
+	return defaultWheel
+}
+
+// After returns a channel that receives the current time once d has
+// elapsed, using a shared package-level TimeWheel so callers don't need
+// to construct one of their own.
+func After(d time.Duration) chan time.Time {
+	return defaultTimeWheel().After(d)
+}
+
+// AfterFunc schedules f to run once d has elapsed, using a shared
+// package-level TimeWheel. See (*TimeWheel).AfterFunc for the calling
+// constraints on f.
+func AfterFunc(d time.Duration, f func()) *Timer {
+	return defaultTimeWheel().AfterFunc(d, f)
+}
+
+// Future schedules f to run once d has elapsed, using the shared
+// package-level TimeWheel. Unlike AfterFunc it does not return the Timer,
+// matching the fire-and-forget shape of gost/gxtime's timer helpers.
+func Future(d time.Duration, f func()) {
+	defaultTimeWheel().AfterFunc(d, f)
+}
+
+// Sleep blocks the calling goroutine until d has elapsed, using the
+// shared package-level TimeWheel.
+func Sleep(d time.Duration) {
+	<-defaultTimeWheel().After(d)
+}
+
+// Now returns the current time of the shared package-level TimeWheel.
+func Now() time.Time {
+	return defaultTimeWheel().Now()
+}
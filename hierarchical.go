@@ -0,0 +1,138 @@
+package timewheel
+
+import "sync"
+
+// Bit widths and sizes for the five cascading levels used by the
+// hierarchical wheel, mirroring the classic Linux kernel timer wheel
+// (tv1..tv5): tv1 holds timers firing within the next 256 ticks, and each
+// higher level covers a progressively coarser range so that a timer
+// scheduled far in the future only occupies a single bucket until it is
+// cascaded down close to its deadline.
+const (
+	tv1Bits = 8
+	tv2Bits = 6
+	tv3Bits = 6
+	tv4Bits = 6
+	tv5Bits = 6
+
+	tv1Size = 1 << tv1Bits
+	tv2Size = 1 << tv2Bits
+	tv3Size = 1 << tv3Bits
+	tv4Size = 1 << tv4Bits
+	tv5Size = 1 << tv5Bits
+
+	tv2Shift = tv1Bits
+	tv3Shift = tv2Shift + tv2Bits
+	tv4Shift = tv3Shift + tv3Bits
+	tv5Shift = tv4Shift + tv4Bits
+)
+
+// levelShift and levelSize describe, per level, the bit offset and bucket
+// count used to derive a slot index from an absolute tick ("jiffies").
+var (
+	levelShift = [5]uint{0, tv2Shift, tv3Shift, tv4Shift, tv5Shift}
+	levelSize  = [5]int64{tv1Size, tv2Size, tv3Size, tv4Size, tv5Size}
+)
+
+// hwheelEntry wraps a timer/ticker with its absolute expiry so that
+// cascading can recompute which level and bucket it belongs in.
+type hwheelEntry[T any] struct {
+	expires int64
+	elem    *T
+}
+
+// hwheel is a five-level cascading timing wheel keyed on an absolute tick
+// counter ("jiffies") instead of a single flat map. Insertion cost and
+// memory are bounded regardless of how far in the future a timer fires,
+// since a far-future timer lives in exactly one bucket of a coarse level
+// until it cascades down. It implements the same slotStore[T] interface
+// as timeslot[T], so it can be swapped in behind NewHierarchical.
+type hwheel[T any] struct {
+	do      func(idx int64, elem *T)
+	jiffies int64
+	levels  [5][][]*hwheelEntry[T]
+	mu      sync.Mutex
+}
+
+// Creates a new hierarchical wheel with all five levels allocated.
+func newHwheel[T any](do func(idx int64, elem *T)) *hwheel[T] {
+	hw := &hwheel[T]{do: do}
+	for lvl, size := range levelSize {
+		hw.levels[lvl] = make([][]*hwheelEntry[T], size)
+	}
+	return hw
+}
+
+// Adds a timer to the wheel, choosing its level from how far idx (its
+// absolute expiry) lies from the current tick.
+func (hw *hwheel[T]) add(idx int64, t *T) *T {
+	hw.mu.Lock()
+	hw.insertLocked(&hwheelEntry[T]{expires: idx, elem: t})
+	hw.mu.Unlock()
+	return t
+}
+
+// insertLocked places e into the lowest level that can represent its
+// remaining distance from now without truncation, per hw.mu.
+func (hw *hwheel[T]) insertLocked(e *hwheelEntry[T]) {
+	remaining := e.expires - hw.jiffies
+	var level int
+	switch {
+	case remaining < tv1Size:
+		level = 0
+	case remaining < 1<<(tv2Shift+tv2Bits):
+		level = 1
+	case remaining < 1<<(tv3Shift+tv3Bits):
+		level = 2
+	case remaining < 1<<(tv4Shift+tv4Bits):
+		level = 3
+	default:
+		level = 4
+	}
+	slot := (e.expires >> levelShift[level]) & (levelSize[level] - 1)
+	hw.levels[level][slot] = append(hw.levels[level][slot], e)
+}
+
+// done advances the wheel by one tick, firing everything due in the
+// current tv1 slot and cascading higher levels down as their inner index
+// wraps. The idx parameter is accepted to satisfy slotStore[T] but is not
+// otherwise used: the wheel tracks its own jiffies in lockstep with the
+// owning TimeWheel's current tick.
+func (hw *hwheel[T]) done(int64) {
+	hw.mu.Lock()
+	hw.jiffies++
+
+	idx1 := hw.jiffies & (tv1Size - 1)
+	if idx1 == 0 {
+		hw.cascadeLocked(1)
+	}
+
+	fire := hw.levels[0][idx1]
+	hw.levels[0][idx1] = nil
+	hw.mu.Unlock()
+
+	for _, e := range fire {
+		hw.do(e.expires, e.elem)
+	}
+}
+
+// cascadeLocked drains level's current bucket and reinserts each entry,
+// which lands it in a lower level (or tv1) based on its now-shorter
+// remaining distance. If that bucket's index is itself 0, the level above
+// has also wrapped and is cascaded in turn.
+func (hw *hwheel[T]) cascadeLocked(level int) {
+	if level >= len(hw.levels) {
+		return
+	}
+
+	idx := (hw.jiffies >> levelShift[level]) & (levelSize[level] - 1)
+	bucket := hw.levels[level][idx]
+	hw.levels[level][idx] = nil
+	for _, e := range bucket {
+		hw.insertLocked(e)
+	}
+
+	if idx == 0 {
+		hw.cascadeLocked(level + 1)
+	}
+}
@@ -0,0 +1,71 @@
+package timewheel
+
+import "testing"
+
+// TestHwheelMemoryBound inserts millions of entries with wildly varying
+// deadlines - from the next tick up to the far edge of tv5 - and checks
+// that the wheel's bucket count stays fixed by its geometry regardless of
+// how many timers are scheduled or how far out they fire, unlike a flat
+// map that grows one entry per absolute tick. A coarse level legitimately
+// carries a large, lumpy share of the entries; it's the number of buckets,
+// not their occupancy, that cascading bounds.
+func TestHwheelMemoryBound(t *testing.T) {
+	const n = 2_000_000
+	hw := newHwheel(func(int64, *int) {})
+
+	for i := 0; i < n; i++ {
+		// Spread deadlines across the whole addressable range, not just
+		// tv1, so entries land in every level.
+		d := int64(i) * 2654435761 % (1 << 32)
+		hw.add(d, new(int))
+	}
+
+	var total int
+	for lvl, want := range levelSize {
+		if int64(len(hw.levels[lvl])) != want {
+			t.Fatalf("level %d holds %d buckets, want the fixed %d", lvl, len(hw.levels[lvl]), want)
+		}
+		for _, bucket := range hw.levels[lvl] {
+			total += len(bucket)
+		}
+	}
+	if total != n {
+		t.Fatalf("levels hold %d entries total, want all %d inserted", total, n)
+	}
+}
+
+// TestHwheelCascadeFiringOrder drives the wheel tick by tick and checks
+// that every timer fires, and fires on the exact tick matching its
+// deadline rather than merely sometime within the budget, as entries
+// cascade down from the coarse levels into tv1. A cascade bug that fires
+// a timer early or late would pass a weaker "fired eventually" check.
+func TestHwheelCascadeFiringOrder(t *testing.T) {
+	const ticks = 1 << 26
+
+	var hw *hwheel[int64]
+	firedAt := make(map[int64]int64)
+	hw = newHwheel(func(_ int64, expires *int64) { firedAt[*expires] = hw.jiffies })
+
+	deadlines := []int64{1, 5, 256, 257, 1 << 14, (1 << 14) + 1, 1 << 20, (1 << 26) - 1}
+	for _, d := range deadlines {
+		expires := d
+		hw.add(d, &expires)
+	}
+
+	for i := int64(1); i <= ticks; i++ {
+		hw.done(i)
+	}
+
+	for _, d := range deadlines {
+		if d > ticks {
+			continue
+		}
+		tick, ok := firedAt[d]
+		if !ok {
+			t.Fatalf("timer with deadline %d never fired within %d ticks", d, ticks)
+		}
+		if tick != d {
+			t.Fatalf("timer with deadline %d fired at tick %d, want exactly %d", d, tick, d)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package timewheel_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HuXin0817/timewheel"
+)
+
+// TestTimerStopReturnsBool checks time.Timer-compatible Stop semantics:
+// true the first time on a pending timer, false afterwards, and no send
+// on C.
+func TestTimerStopReturnsBool(t *testing.T) {
+	clock := timewheel.NewManualClock(time.Unix(0, 0))
+	tw := timewheel.NewWithClock(100*time.Millisecond, clock)
+	defer tw.Stop()
+
+	timer := tw.NewTimer(500 * time.Millisecond)
+
+	if !timer.Stop() {
+		t.Fatal("Stop on a pending timer should return true")
+	}
+	if timer.Stop() {
+		t.Fatal("Stop on an already-stopped timer should return false")
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-timer.C:
+		t.Fatal("a stopped timer must not send on C")
+	default:
+	}
+}
+
+// TestTimerResetRearmsAtNewDeadline checks that Reset moves a timer to a
+// new deadline rather than letting it fire at its original one, and
+// reports whether it was still pending beforehand.
+func TestTimerResetRearmsAtNewDeadline(t *testing.T) {
+	clock := timewheel.NewManualClock(time.Unix(0, 0))
+	tw := timewheel.NewWithClock(100*time.Millisecond, clock)
+	defer tw.Stop()
+
+	timer := tw.NewTimer(200 * time.Millisecond)
+
+	if !timer.Reset(500 * time.Millisecond) {
+		t.Fatal("Reset on a pending timer should report it was active")
+	}
+
+	clock.Advance(200 * time.Millisecond)
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired at its original deadline despite Reset")
+	default:
+	}
+
+	clock.Advance(300 * time.Millisecond)
+	select {
+	case <-timer.C:
+	default:
+		t.Fatal("timer did not fire at its Reset deadline")
+	}
+}
+
+// TestTickerResetRebucketsImmediately checks that Ticker.Reset takes
+// effect on the very next tick, rather than only after the ticker's
+// original interval has elapsed once more.
+func TestTickerResetRebucketsImmediately(t *testing.T) {
+	clock := timewheel.NewManualClock(time.Unix(0, 0))
+	tw := timewheel.NewWithClock(100*time.Millisecond, clock)
+	defer tw.Stop()
+
+	ticker := tw.NewTicker(time.Second)
+	ticker.Reset(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	clock.Advance(200 * time.Millisecond)
+	select {
+	case <-ticker.C:
+	default:
+		t.Fatal("ticker did not fire at its new, shorter interval")
+	}
+}
@@ -10,19 +10,27 @@ const minInterval = 10 * time.Millisecond // Defines the minimum interval as 10
 
 // timeslot struct represents a time slot, containing a callback function and a map for storing timers
 type timeslot[T any] struct {
-	do   func(elem *T)  // Callback function to be executed for each element
-	slot map[int64][]*T // Map to store timers, keyed by their index
-	mu   sync.Mutex     // Mutex for synchronizing access to the slot map
+	do   func(idx int64, elem *T) // Callback function to be executed for each element, given the index it fired at
+	slot map[int64][]*T           // Map to store timers, keyed by their index
+	mu   sync.Mutex               // Mutex for synchronizing access to the slot map
 }
 
 // Creates a new instance of timeslot
-func newTimeslot[T any](do func(elem *T)) *timeslot[T] {
+func newTimeslot[T any](do func(idx int64, elem *T)) *timeslot[T] {
 	return &timeslot[T]{
 		do:   do,
 		slot: make(map[int64][]*T),
 	}
 }
 
+// slotStore is the storage strategy behind a TimeWheel's timers and
+// tickers. timeslot[T] is the original flat map; hwheel[T] is the
+// cascading hierarchical variant used by NewHierarchical.
+type slotStore[T any] interface {
+	add(idx int64, t *T) *T
+	done(idx int64)
+}
+
 // Adds a timer to the timeslot at the specified index
 func (ts *timeslot[T]) add(idx int64, t *T) *T {
 	ts.mu.Lock()
@@ -38,30 +46,70 @@ func (ts *timeslot[T]) add(idx int64, t *T) *T {
 
 // Executes the callback for all timers at the specified index and removes them from the slot
 func (ts *timeslot[T]) done(idx int64) {
-	if s, ok := ts.slot[idx]; ok {
+	ts.mu.Lock()
+	s, ok := ts.slot[idx]
+	delete(ts.slot, idx)
+	ts.mu.Unlock()
+
+	if ok {
 		for _, t := range s {
-			ts.do(t)
+			ts.do(idx, t)
 		}
-		ts.mu.Lock()
-		delete(ts.slot, idx)
-		ts.mu.Unlock()
 	}
 }
 
+// Timer lifecycle states, tracked via state so Stop and Reset can report
+// whether they acted on a still-pending timer, matching time.Timer.
+const (
+	timerActive int32 = iota
+	timerFired
+	timerStopped
+)
+
 // Timer struct represents a single timer
 type Timer struct {
-	C      chan time.Time // Channel to signal when the timer fires
-	belong *TimeWheel     // Reference to the TimeWheel to which this timer belongs
-	stop   atomic.Bool    // Atomic boolean to indicate if the timer is stopped
+	C       chan time.Time // Channel to signal when the timer fires; nil for func-mode timers
+	belong  *TimeWheel     // Reference to the TimeWheel to which this timer belongs
+	fn      func()         // Set for func-mode timers created via AfterFunc; invoked inline when due
+	slotIdx atomic.Int64   // Absolute tick index of the bucket this timer currently lives in
+	state   atomic.Int32   // One of timerActive, timerFired, timerStopped
 }
 
-// Stops the timer
-func (t *Timer) Stop() {
-	if t.stop.Load() {
+// fire is invoked by the owning TimeWheel when this timer's bucket comes
+// due. It runs the callback for func-mode timers (AfterFunc), or
+// delivers the current time on C for channel-mode timers (NewTimer,
+// After). It is a no-op if the timer was already stopped or had already
+// fired.
+func (t *Timer) fire() {
+	if !t.state.CompareAndSwap(timerActive, timerFired) {
+		return
+	}
+	if t.fn != nil {
+		t.fn()
 		return
 	}
-	t.stop.Store(true)
-	t.C <- t.belong.now
+	t.C <- t.belong.Now()
+}
+
+// Stop prevents the timer from firing, matching time.Timer.Stop: it
+// returns true if the call stops the timer, false if the timer had
+// already expired or already been stopped. Unlike the channel-pushing
+// behaviour this replaces, it never sends on C.
+func (t *Timer) Stop() bool {
+	return t.state.CompareAndSwap(timerActive, timerStopped)
+}
+
+// Reset re-arms the timer to fire after d, as if NewTimer (or AfterFunc)
+// had just been called with d, and reports whether the timer was still
+// pending beforehand, matching time.Timer.Reset. As with time.Timer,
+// calling Reset on a timer whose channel may still hold an unread value
+// from a previous firing is racy; drain C first if that matters.
+func (t *Timer) Reset(d time.Duration) bool {
+	wasActive := t.state.Swap(timerActive) == timerActive
+	idx := t.belong.current.Load() + t.belong.increment(d)
+	t.slotIdx.Store(idx)
+	t.belong.timerSlot.add(idx, t)
+	return wasActive
 }
 
 // Ticker struct represents a repeating ticker
@@ -69,76 +117,150 @@ type Ticker struct {
 	C         chan time.Time // Channel to signal when the ticker fires
 	belong    *TimeWheel     // Reference to the TimeWheel to which this ticker belongs
 	increment atomic.Int64   // Atomic integer to store the tick interval in terms of TimeWheel ticks
+	slotIdx   atomic.Int64   // Absolute tick index of the bucket this ticker currently lives in
 	stop      atomic.Bool    // Atomic boolean to indicate if the ticker is stopped
 }
 
-// Resets the ticker to fire at the specified duration
+// Resets the ticker to fire at the specified duration, re-bucketing it
+// immediately rather than waiting for the next natural fire to pick up
+// the new interval.
 func (t *Ticker) Reset(d time.Duration) {
-	t.increment.Store(int64(d / t.belong.interval))
+	t.increment.Store(t.belong.increment(d))
+	idx := t.belong.current.Load() + t.belong.increment(d)
+	t.slotIdx.Store(idx)
+	t.belong.tickerSlot.add(idx, t)
 }
 
-// Stops the ticker
+// Stops the ticker, matching time.Ticker.Stop. Unlike the channel-pushing
+// behaviour this replaces, it never sends on C. Stop and the NewTickerCtx
+// reaper share the same CAS in stopNoSend, so only one of them ever wins
+// the transition and only the reaper's win closes C.
 func (t *Ticker) Stop() {
-	if t.stop.Load() {
-		return
-	}
-	t.stop.Store(true)
-	t.C <- t.belong.now
+	t.stopNoSend()
+}
+
+// stopNoSend is Stop's CAS, reporting whether this call was the one that
+// stopped the ticker. Exposed separately so the NewTickerCtx reaper
+// callback, which runs on the TimeWheel's own tick goroutine, can tell
+// whether it should be the one to close C.
+func (t *Ticker) stopNoSend() bool {
+	return t.stop.CompareAndSwap(false, true)
 }
 
 // TimeWheel struct represents the core of the timing wheel
 type TimeWheel struct {
-	now        time.Time         // Current time
-	ticker     *time.Ticker      // Go Ticker to drive the TimeWheel
-	current    int64             // Current tick index
-	interval   time.Duration     // Duration of each tick
-	timerSlot  *timeslot[Timer]  // Slot for managing timers
-	tickerSlot *timeslot[Ticker] // Slot for managing tickers
-	stop       atomic.Bool       // Atomic boolean to indicate if the TimeWheel is stopped
+	now        atomic.Pointer[time.Time] // Current time; written by the tick goroutine, read via Now()
+	ticker     ClockTicker               // Ticker, from a Clock, that drives the TimeWheel
+	current    atomic.Int64              // Current tick index
+	interval   time.Duration             // Duration of each tick
+	timerSlot  slotStore[Timer]          // Slot for managing timers
+	tickerSlot slotStore[Ticker]         // Slot for managing tickers
+	ctxReaper  ctxReaper                 // Batches context-cancellation watching for AfterCtx/NewTickerCtx
+	stop       atomic.Bool               // Atomic boolean to indicate if the TimeWheel is stopped
 }
 
-// Creates a new TimeWheel with the specified interval
-func New(interval time.Duration) (tw *TimeWheel) {
+// newTimeWheel builds a TimeWheel around the given clock and timer/ticker
+// slotStore implementations and starts its tick goroutine. New,
+// NewWithClock and NewHierarchical all funnel through here and differ
+// only in which Clock and slotStore they wire up.
+func newTimeWheel(interval time.Duration, clock Clock, timerSlot slotStore[Timer], newTickerSlot func(tw *TimeWheel) slotStore[Ticker]) (tw *TimeWheel) {
 	if interval < minInterval {
 		interval = minInterval
 	}
 
 	tw = &TimeWheel{
-		now:       time.Now(),
 		interval:  interval,
-		ticker:    time.NewTicker(interval),
-		timerSlot: newTimeslot(func(t *Timer) { t.Stop() }),
-		tickerSlot: newTimeslot(func(t *Ticker) {
-			if t.stop.Load() {
-				return
-			}
-			t.C <- tw.now
-			tw.tickerSlot.add(tw.current+t.increment.Load(), t)
-		}),
+		ticker:    clock.NewTicker(interval),
+		timerSlot: timerSlot,
 	}
+	tw.setNow(clock.Now())
+	tw.tickerSlot = newTickerSlot(tw)
 
 	go func() {
-		for tw.now = range tw.ticker.C {
+		for now := range tw.ticker.Chan() {
 			if tw.stop.Load() {
 				return
 			}
-			tw.current++
-			tw.timerSlot.done(tw.current)
-			tw.tickerSlot.done(tw.current)
+			tw.setNow(now)
+			current := tw.current.Add(1)
+			tw.timerSlot.done(current)
+			tw.tickerSlot.done(current)
+			tw.ctxReaper.check()
+			if obs, ok := tw.ticker.(tickObserver); ok {
+				obs.observeTick()
+			}
 		}
 	}()
 
 	return
 }
 
+// setNow atomically publishes t as the TimeWheel's current time.
+func (tw *TimeWheel) setNow(t time.Time) {
+	tw.now.Store(&t)
+}
+
+// tickerDo builds the per-tick callback shared by every tickerSlot
+// implementation: skip stale entries left behind by Reset, deliver the
+// tick, then re-add the ticker at its next deadline unless it has been
+// stopped.
+func tickerDo(tw *TimeWheel) func(idx int64, t *Ticker) {
+	return func(idx int64, t *Ticker) {
+		if t.slotIdx.Load() != idx {
+			return
+		}
+		if t.stop.Load() {
+			return
+		}
+		t.C <- tw.Now()
+		next := tw.current.Load() + t.increment.Load()
+		t.slotIdx.Store(next)
+		tw.tickerSlot.add(next, t)
+	}
+}
+
+// Creates a new TimeWheel with the specified interval, backed by a flat
+// map keyed on absolute tick index and driven by the real wall clock.
+func New(interval time.Duration) (tw *TimeWheel) {
+	return NewWithClock(interval, realClock{})
+}
+
+// Creates a new TimeWheel with the specified interval, driven by the
+// given Clock instead of the wall clock. Tests can pass a ManualClock to
+// advance the wheel deterministically without sleeping.
+func NewWithClock(interval time.Duration, c Clock) (tw *TimeWheel) {
+	return newTimeWheel(interval, c, newTimeslot(fireTimerAt), func(tw *TimeWheel) slotStore[Ticker] {
+		return newTimeslot(tickerDo(tw))
+	})
+}
+
+// Creates a new TimeWheel backed by a five-level hierarchical (cascading)
+// wheel instead of a flat map, so that far-future timers no longer pin a
+// slot for their entire wait and scheduling stays O(1) amortized no
+// matter how long timers run. See hwheel for the cascading algorithm.
+func NewHierarchical(tick time.Duration) (tw *TimeWheel) {
+	return newTimeWheel(tick, realClock{}, newHwheel(fireTimerAt), func(tw *TimeWheel) slotStore[Ticker] {
+		return newHwheel(tickerDo(tw))
+	})
+}
+
+// fireTimerAt is the per-tick callback shared by every timerSlot
+// implementation: it skips stale entries left behind by Timer.Reset and
+// fires the timer otherwise.
+func fireTimerAt(idx int64, t *Timer) {
+	if t.slotIdx.Load() == idx {
+		t.fire()
+	}
+}
+
 // Returns the current time of the TimeWheel
 func (tw *TimeWheel) Now() time.Time {
-	return tw.now
+	return *tw.now.Load()
 }
 
 // Returns the duration since the specified time
 func (tw *TimeWheel) Since(t time.Time) time.Duration {
-	return tw.now.Sub(t)
+	return tw.Now().Sub(t)
 }
 
 // Returns a channel that will receive the current time after the specified duration
@@ -168,20 +290,39 @@ func (tw *TimeWheel) increment(d time.Duration) int64 {
 
 // Creates a new timer that will fire after the specified duration
 func (tw *TimeWheel) NewTimer(d time.Duration) *Timer {
-	idx := tw.current + tw.increment(d)
-	return tw.timerSlot.add(idx, &Timer{
+	idx := tw.current.Load() + tw.increment(d)
+	t := &Timer{
 		C:      make(chan time.Time, 1),
 		belong: tw,
-	})
+	}
+	t.slotIdx.Store(idx)
+	return tw.timerSlot.add(idx, t)
+}
+
+// Creates a timer that invokes f instead of delivering on a channel: f
+// runs inline from the TimeWheel's tick goroutine once d has elapsed, so
+// keep it short or hand it off to a worker goroutine, since it blocks the
+// wheel - and every other timer/ticker due on the same tick - until it
+// returns.
+func (tw *TimeWheel) AfterFunc(d time.Duration, f func()) *Timer {
+	idx := tw.current.Load() + tw.increment(d)
+	t := &Timer{
+		belong: tw,
+		fn:     f,
+	}
+	t.slotIdx.Store(idx)
+	return tw.timerSlot.add(idx, t)
 }
 
 // Creates a new ticker that will fire at the specified interval
 func (tw *TimeWheel) NewTicker(d time.Duration) *Ticker {
 	increment := tw.increment(d)
+	idx := tw.current.Load() + increment
 	ticker := &Ticker{
 		C:      make(chan time.Time, 1),
 		belong: tw,
 	}
 	ticker.increment.Store(increment)
-	return tw.tickerSlot.add(tw.current+increment, ticker)
+	ticker.slotIdx.Store(idx)
+	return tw.tickerSlot.add(idx, ticker)
 }